@@ -9,14 +9,105 @@ import (
 	"sync"
 	"time"
 
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/common/log"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 const (
 	namespace = "qrator"
 )
 
+var (
+	apiInFlightRequests = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "api_client_in_flight_requests",
+		Help:      "Current number of in-flight requests to the Qrator API",
+	})
+
+	apiRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "api_client_requests_total",
+		Help:      "Total number of requests made to the Qrator API",
+	}, []string{"method", "code"})
+
+	apiRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "api_client_request_duration_seconds",
+		Help:      "Histogram of Qrator API request latencies",
+	}, []string{"method"})
+
+	apiDNSDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "api_client_dns_duration_seconds",
+		Help:      "Histogram of DNS lookup latencies for Qrator API requests",
+	}, []string{"event"})
+
+	apiConnectDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "api_client_connect_duration_seconds",
+		Help:      "Histogram of connection establishment latencies for Qrator API requests",
+	}, []string{"event"})
+
+	apiTLSDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "api_client_tls_duration_seconds",
+		Help:      "Histogram of TLS handshake latencies for Qrator API requests",
+	}, []string{"event"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		apiInFlightRequests,
+		apiRequestsTotal,
+		apiRequestDuration,
+		apiDNSDuration,
+		apiConnectDuration,
+		apiTLSDuration,
+	)
+}
+
+// newQratorAPIClient builds an http.Client whose RoundTripper is
+// instrumented with the standard prometheus/client_golang promhttp
+// wrappers, giving visibility into the exporter's own upstream calls to
+// the Qrator API.
+func newQratorAPIClient() *http.Client {
+	trace := &promhttp.InstrumentTrace{
+		DNSStart: func(t float64) {
+			apiDNSDuration.WithLabelValues("dns_start").Observe(t)
+		},
+		DNSDone: func(t float64) {
+			apiDNSDuration.WithLabelValues("dns_done").Observe(t)
+		},
+		ConnectStart: func(t float64) {
+			apiConnectDuration.WithLabelValues("connect_start").Observe(t)
+		},
+		ConnectDone: func(t float64) {
+			apiConnectDuration.WithLabelValues("connect_done").Observe(t)
+		},
+		TLSHandshakeStart: func(t float64) {
+			apiTLSDuration.WithLabelValues("tls_handshake_start").Observe(t)
+		},
+		TLSHandshakeDone: func(t float64) {
+			apiTLSDuration.WithLabelValues("tls_handshake_done").Observe(t)
+		},
+	}
+
+	transport := promhttp.InstrumentRoundTripperInFlight(apiInFlightRequests,
+		promhttp.InstrumentRoundTripperCounter(apiRequestsTotal,
+			promhttp.InstrumentRoundTripperTrace(trace,
+				promhttp.InstrumentRoundTripperDuration(apiRequestDuration, http.DefaultTransport),
+			),
+		),
+	)
+
+	return &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: transport,
+	}
+}
+
 type qratorRequest struct {
 	Method string `json:"method"`
 	Params string `json:"params"`
@@ -36,31 +127,35 @@ type qratorDomains struct {
 	ID      int            `json:"id"`
 }
 
+// qratorStatValues is the set of counters Qrator reports for a domain at a
+// single point in time.
+type qratorStatValues struct {
+	Bsend        float64 `json:"bsend"`
+	Brecv        float64 `json:"brecv"`
+	Bout         float64 `json:"bout"`
+	Psend        float64 `json:"psend"`
+	Precv        float64 `json:"precv"`
+	Reqspeed     float64 `json:"reqspeed"`
+	Reqlonger10S int     `json:"reqlonger10s"`
+	Reqlonger07S int     `json:"reqlonger07s"`
+	Reqlonger05S int     `json:"reqlonger05s"`
+	Reqlonger02S int     `json:"reqlonger02s"`
+	Reqall       int     `json:"reqall"`
+	Err50X       int     `json:"err50x"`
+	Err501       int     `json:"err501"`
+	Err502       int     `json:"err502"`
+	Err503       int     `json:"err503"`
+	Err504       int     `json:"err504"`
+	Ban          int     `json:"ban"`
+	BanAPI       int     `json:"ban_api"`
+	BanWAF       int     `json:"ban_waf"`
+	Billable     int     `json:"billable"`
+}
+
 type qratorDomainStat struct {
-	Result struct {
-		Bsend        float64 `json:"bsend"`
-		Brecv        float64 `json:"brecv"`
-		Bout         float64 `json:"bout"`
-		Psend        float64 `json:"psend"`
-		Precv        float64 `json:"precv"`
-		Reqspeed     float64 `json:"reqspeed"`
-		Reqlonger10S int     `json:"reqlonger10s"`
-		Reqlonger07S int     `json:"reqlonger07s"`
-		Reqlonger05S int     `json:"reqlonger05s"`
-		Reqlonger02S int     `json:"reqlonger02s"`
-		Reqall       int     `json:"reqall"`
-		Err50X       int     `json:"err50x"`
-		Err501       int     `json:"err501"`
-		Err502       int     `json:"err502"`
-		Err503       int     `json:"err503"`
-		Err504       int     `json:"err504"`
-		Ban          int     `json:"ban"`
-		BanAPI       int     `json:"ban_api"`
-		BanWAF       int     `json:"ban_waf"`
-		Billable     int     `json:"billable"`
-	} `json:"result"`
-	Error string `json:"error"`
-	ID    int    `json:"id"`
+	Result qratorStatValues `json:"result"`
+	Error  string           `json:"error"`
+	ID     int              `json:"id"`
 }
 
 type qratorPing struct {
@@ -74,41 +169,101 @@ type Collector struct {
 	auth         string
 	clientID     int
 	qratorAPIURL string
-
-	BypassedTraffic   prometheus.GaugeVec
-	IncomingTraffic   prometheus.GaugeVec
-	OutgoingTraffic   prometheus.GaugeVec
-	BypassedPackets   prometheus.GaugeVec
-	IncomingPackets   prometheus.GaugeVec
-	RequestRate       prometheus.GaugeVec
-	SlowRequestsCount prometheus.GaugeVec
-	RequestsCount     prometheus.GaugeVec
-	ErrorsCount       prometheus.GaugeVec
-	BannedIPs         prometheus.GaugeVec
-	BillableTraffic   prometheus.GaugeVec
+	httpClient   *http.Client
+	logger       log.Logger
+
+	// targetDomain, when non-empty, restricts Collect to a single domain.
+	// It is used by the /probe handler to scope a throwaway Collector to
+	// the domain being probed; the singleton /metrics Collector leaves it
+	// empty and scrapes every domain on the account.
+	targetDomain string
+
+	bypassedTrafficDesc   *prometheus.Desc
+	incomingTrafficDesc   *prometheus.Desc
+	outgoingTrafficDesc   *prometheus.Desc
+	bypassedPacketsDesc   *prometheus.Desc
+	incomingPacketsDesc   *prometheus.Desc
+	requestRateDesc       *prometheus.Desc
+	slowRequestsCountDesc *prometheus.Desc
+	requestsCountDesc     *prometheus.Desc
+	errorsCountDesc       *prometheus.Desc
+	bannedIPsDesc         *prometheus.Desc
+	billableTrafficDesc   *prometheus.Desc
+	scrapeWarningsDesc    *prometheus.Desc
 
 	totalScrapes             prometheus.Counter
 	failedDomainScrapes      prometheus.Counter
 	failedDomainStatsScrapes prometheus.Counter
 
-	sync.Mutex
+	warningsMu sync.Mutex
+	warnings   []string
+}
+
+// qratorWarning carries a single non-fatal scrape warning back from a
+// domain goroutine to the collecting goroutine.
+type qratorWarning struct {
+	Domain string
+	Reason string
+}
+
+// LastWarnings returns the non-fatal warnings collected during the most
+// recent scrape. It is safe to call concurrently with Collect.
+func (c *Collector) LastWarnings() []string {
+	c.warningsMu.Lock()
+	defer c.warningsMu.Unlock()
+
+	warnings := make([]string, len(c.warnings))
+	copy(warnings, c.warnings)
+	return warnings
 }
 
 // Describe for prometheus.Collector interface implementation
 func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
-	prometheus.DescribeByCollect(c, ch)
+	ch <- c.bypassedTrafficDesc
+	ch <- c.incomingTrafficDesc
+	ch <- c.outgoingTrafficDesc
+	ch <- c.bypassedPacketsDesc
+	ch <- c.incomingPacketsDesc
+	ch <- c.requestRateDesc
+	ch <- c.slowRequestsCountDesc
+	ch <- c.requestsCountDesc
+	ch <- c.errorsCountDesc
+	ch <- c.bannedIPsDesc
+	ch <- c.billableTrafficDesc
+	ch <- c.scrapeWarningsDesc
+	ch <- c.totalScrapes.Desc()
+	ch <- c.failedDomainScrapes.Desc()
+	ch <- c.failedDomainStatsScrapes.Desc()
 }
 
-// Collect for prometheus.Collector interface implementation
+// Collect for prometheus.Collector interface implementation. Metrics are
+// built as ephemeral const metrics rather than stored on the Collector, so
+// concurrent scrapes never serialize on each other and a domain that no
+// longer exists stops being exported on the very next scrape instead of
+// lingering at its last known value.
 func (c *Collector) Collect(ch chan<- prometheus.Metric) {
-	c.Lock()
-	defer c.Unlock()
-
 	c.totalScrapes.Inc()
+
+	warnings := make(chan qratorWarning, 1)
+	done := make(chan struct{})
+	var collected []string
+	go func() {
+		for w := range warnings {
+			collected = append(collected, fmt.Sprintf("%s: %s", w.Domain, w.Reason))
+			ch <- prometheus.MustNewConstMetric(c.scrapeWarningsDesc, prometheus.GaugeValue, 1, w.Domain, w.Reason)
+		}
+		close(done)
+	}()
+
 	qds, err := c.getQratorDomains()
 	if err != nil {
 		c.failedDomainScrapes.Inc()
+		warnings <- qratorWarning{Domain: "", Reason: err.Error()}
 	}
+	if c.targetDomain != "" {
+		qds = filterDomains(qds, c.targetDomain)
+	}
+
 	wg := &sync.WaitGroup{}
 	for _, qd := range qds {
 		wg.Add(1)
@@ -117,55 +272,55 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 			s, err := c.getQratorDomainStats(qd)
 			if err != nil {
 				c.failedDomainStatsScrapes.Inc()
+				warnings <- qratorWarning{Domain: qd.Name, Reason: err.Error()}
 				return
 			}
-			c.BypassedTraffic.WithLabelValues(qd.Name).Set(s.Result.Bsend)
-			c.IncomingTraffic.WithLabelValues(qd.Name).Set(s.Result.Brecv)
-			c.OutgoingTraffic.WithLabelValues(qd.Name).Set(s.Result.Bout)
-			c.BypassedPackets.WithLabelValues(qd.Name).Set(s.Result.Psend)
-			c.IncomingPackets.WithLabelValues(qd.Name).Set(s.Result.Precv)
-			c.RequestRate.WithLabelValues(qd.Name).Set(s.Result.Reqspeed)
-			c.SlowRequestsCount.WithLabelValues(qd.Name, "0.2").Set(float64(s.Result.Reqlonger02S))
-			c.SlowRequestsCount.WithLabelValues(qd.Name, "0.5").Set(float64(s.Result.Reqlonger05S))
-			c.SlowRequestsCount.WithLabelValues(qd.Name, "0.7").Set(float64(s.Result.Reqlonger07S))
-			c.SlowRequestsCount.WithLabelValues(qd.Name, "1.0").Set(float64(s.Result.Reqlonger10S))
-			c.RequestsCount.WithLabelValues(qd.Name).Set(float64(s.Result.Reqall))
-			c.ErrorsCount.WithLabelValues(qd.Name, "50X").Set(float64(s.Result.Err50X))
-			c.ErrorsCount.WithLabelValues(qd.Name, "501").Set(float64(s.Result.Err501))
-			c.ErrorsCount.WithLabelValues(qd.Name, "502").Set(float64(s.Result.Err502))
-			c.ErrorsCount.WithLabelValues(qd.Name, "503").Set(float64(s.Result.Err503))
-			c.ErrorsCount.WithLabelValues(qd.Name, "504").Set(float64(s.Result.Err504))
-			c.BannedIPs.WithLabelValues(qd.Name, "Qrator").Set(float64(s.Result.Ban))
-			c.BannedIPs.WithLabelValues(qd.Name, "Qrator.API").Set(float64(s.Result.BanAPI))
-			c.BannedIPs.WithLabelValues(qd.Name, "WAF").Set(float64(s.Result.BanWAF))
-			c.BillableTraffic.WithLabelValues(qd.Name).Set(float64(s.Result.Billable))
-
-			ch <- c.BypassedTraffic.WithLabelValues(qd.Name)
-			ch <- c.IncomingTraffic.WithLabelValues(qd.Name)
-			ch <- c.OutgoingTraffic.WithLabelValues(qd.Name)
-			ch <- c.BypassedPackets.WithLabelValues(qd.Name)
-			ch <- c.IncomingPackets.WithLabelValues(qd.Name)
-			ch <- c.RequestRate.WithLabelValues(qd.Name)
-			ch <- c.SlowRequestsCount.WithLabelValues(qd.Name, "0.2")
-			ch <- c.SlowRequestsCount.WithLabelValues(qd.Name, "0.5")
-			ch <- c.SlowRequestsCount.WithLabelValues(qd.Name, "0.7")
-			ch <- c.SlowRequestsCount.WithLabelValues(qd.Name, "1.0")
-			ch <- c.RequestsCount.WithLabelValues(qd.Name)
-			ch <- c.ErrorsCount.WithLabelValues(qd.Name, "50X")
-			ch <- c.ErrorsCount.WithLabelValues(qd.Name, "501")
-			ch <- c.ErrorsCount.WithLabelValues(qd.Name, "502")
-			ch <- c.ErrorsCount.WithLabelValues(qd.Name, "503")
-			ch <- c.ErrorsCount.WithLabelValues(qd.Name, "504")
-			ch <- c.BannedIPs.WithLabelValues(qd.Name, "Qrator")
-			ch <- c.BannedIPs.WithLabelValues(qd.Name, "Qrator.API")
-			ch <- c.BannedIPs.WithLabelValues(qd.Name, "WAF")
-			ch <- c.BillableTraffic.WithLabelValues(qd.Name)
+
+			ch <- prometheus.MustNewConstMetric(c.bypassedTrafficDesc, prometheus.GaugeValue, s.Result.Bsend, qd.Name)
+			ch <- prometheus.MustNewConstMetric(c.incomingTrafficDesc, prometheus.GaugeValue, s.Result.Brecv, qd.Name)
+			ch <- prometheus.MustNewConstMetric(c.outgoingTrafficDesc, prometheus.GaugeValue, s.Result.Bout, qd.Name)
+			ch <- prometheus.MustNewConstMetric(c.bypassedPacketsDesc, prometheus.GaugeValue, s.Result.Psend, qd.Name)
+			ch <- prometheus.MustNewConstMetric(c.incomingPacketsDesc, prometheus.GaugeValue, s.Result.Precv, qd.Name)
+			ch <- prometheus.MustNewConstMetric(c.requestRateDesc, prometheus.GaugeValue, s.Result.Reqspeed, qd.Name)
+			ch <- prometheus.MustNewConstMetric(c.slowRequestsCountDesc, prometheus.GaugeValue, float64(s.Result.Reqlonger02S), qd.Name, "0.2")
+			ch <- prometheus.MustNewConstMetric(c.slowRequestsCountDesc, prometheus.GaugeValue, float64(s.Result.Reqlonger05S), qd.Name, "0.5")
+			ch <- prometheus.MustNewConstMetric(c.slowRequestsCountDesc, prometheus.GaugeValue, float64(s.Result.Reqlonger07S), qd.Name, "0.7")
+			ch <- prometheus.MustNewConstMetric(c.slowRequestsCountDesc, prometheus.GaugeValue, float64(s.Result.Reqlonger10S), qd.Name, "1.0")
+			ch <- prometheus.MustNewConstMetric(c.requestsCountDesc, prometheus.GaugeValue, float64(s.Result.Reqall), qd.Name)
+			ch <- prometheus.MustNewConstMetric(c.errorsCountDesc, prometheus.GaugeValue, float64(s.Result.Err50X), qd.Name, "50X")
+			ch <- prometheus.MustNewConstMetric(c.errorsCountDesc, prometheus.GaugeValue, float64(s.Result.Err501), qd.Name, "501")
+			ch <- prometheus.MustNewConstMetric(c.errorsCountDesc, prometheus.GaugeValue, float64(s.Result.Err502), qd.Name, "502")
+			ch <- prometheus.MustNewConstMetric(c.errorsCountDesc, prometheus.GaugeValue, float64(s.Result.Err503), qd.Name, "503")
+			ch <- prometheus.MustNewConstMetric(c.errorsCountDesc, prometheus.GaugeValue, float64(s.Result.Err504), qd.Name, "504")
+			ch <- prometheus.MustNewConstMetric(c.bannedIPsDesc, prometheus.GaugeValue, float64(s.Result.Ban), qd.Name, "Qrator")
+			ch <- prometheus.MustNewConstMetric(c.bannedIPsDesc, prometheus.GaugeValue, float64(s.Result.BanAPI), qd.Name, "Qrator.API")
+			ch <- prometheus.MustNewConstMetric(c.bannedIPsDesc, prometheus.GaugeValue, float64(s.Result.BanWAF), qd.Name, "WAF")
+			ch <- prometheus.MustNewConstMetric(c.billableTrafficDesc, prometheus.GaugeValue, float64(s.Result.Billable), qd.Name)
 		}(qd, ch, wg)
 	}
 
 	wg.Wait()
+	close(warnings)
+	<-done
+
+	c.warningsMu.Lock()
+	c.warnings = collected
+	c.warningsMu.Unlock()
+
 	ch <- c.totalScrapes
 	ch <- c.failedDomainScrapes
+	ch <- c.failedDomainStatsScrapes
+}
+
+// filterDomains returns the subset of qds whose Name matches domain.
+func filterDomains(qds []qratorDomain, domain string) []qratorDomain {
+	filtered := make([]qratorDomain, 0, 1)
+	for _, qd := range qds {
+		if qd.Name == domain {
+			filtered = append(filtered, qd)
+		}
+	}
+	return filtered
 }
 
 func (c *Collector) qratorPostRequest(methodClass string, id int, method string) (*http.Response, error) {
@@ -177,19 +332,21 @@ func (c *Collector) qratorPostRequest(methodClass string, id int, method string)
 	b, _ := json.Marshal(reqBody)
 	req, err := http.NewRequest("POST", reqURL, bytes.NewBuffer(b))
 	if err != nil {
-		log.Errorf("Cannot create new request: %v", err)
+		level.Error(c.logger).Log("msg", "Cannot create new request", "method", method, "err", err)
 		return nil, fmt.Errorf("Cannot create new request: %v", err)
 	}
 	defer req.Body.Close()
 	req.Header.Add("Content-Type", "application/json")
 	req.Header.Add("X-Qrator-Auth", c.auth)
 
-	client := &http.Client{Timeout: 5 * time.Second}
-	response, err := client.Do(req)
+	response, err := c.httpClient.Do(req)
 	if err != nil {
-		log.Errorf("Cannot make new request: %v", err)
+		level.Error(c.logger).Log("msg", "Cannot make new request", "method", method, "err", err)
 		return nil, err
 	}
+	if response.StatusCode != http.StatusOK {
+		level.Warn(c.logger).Log("msg", "Got non-200 response from Qrator API", "method", method, "http_status", response.StatusCode)
+	}
 
 	return response, nil
 }
@@ -197,7 +354,7 @@ func (c *Collector) qratorPostRequest(methodClass string, id int, method string)
 func (c *Collector) getQratorDomainStats(qd qratorDomain) (qratorDomainStat, error) {
 	r, err := c.qratorPostRequest("domain", qd.ID, "statistics_get")
 	if err != nil {
-		log.Errorf("Got an error on domain stats request: %v", err)
+		level.Error(c.logger).Log("msg", "Got an error on domain stats request", "domain", qd.Name, "method", "statistics_get", "err", err)
 		return qratorDomainStat{}, fmt.Errorf("Got an error on domain stats request: %v", err)
 	}
 	defer r.Body.Close()
@@ -205,12 +362,12 @@ func (c *Collector) getQratorDomainStats(qd qratorDomain) (qratorDomainStat, err
 	s := qratorDomainStat{}
 	err = json.NewDecoder(r.Body).Decode(&s)
 	if err != nil {
-		log.Errorf("Got an error on domain stats parsing: %v", err)
+		level.Error(c.logger).Log("msg", "Got an error on domain stats parsing", "domain", qd.Name, "err", err)
 		return qratorDomainStat{}, fmt.Errorf("Got an error on domain stats parsing: %v", err)
 	}
 
 	if s.Error != "" {
-		log.Errorf("Got error in domain stats response: %v", s.Error)
+		level.Error(c.logger).Log("msg", "Got error in domain stats response", "domain", qd.Name, "qrator_error", s.Error)
 		return qratorDomainStat{}, fmt.Errorf("Got error in domain stats response: %v", s.Error)
 	}
 
@@ -227,11 +384,11 @@ func (c *Collector) getQratorDomains() ([]qratorDomain, error) {
 
 	err = json.NewDecoder(r.Body).Decode(&qds)
 	if err != nil {
-		log.Errorf("Can't decode received json: %v", err)
+		level.Error(c.logger).Log("msg", "Can't decode received json", "method", "domains_get", "err", err)
 		return nil, err
 	}
 	if qds.Error != "" {
-		log.Errorf("Wrong request: %s", qds.Error)
+		level.Error(c.logger).Log("msg", "Wrong request", "method", "domains_get", "qrator_error", qds.Error)
 		return nil, fmt.Errorf("Wrong request: %s", qds.Error)
 	}
 
@@ -257,8 +414,8 @@ func (c *Collector) qratorCheck() error {
 }
 
 // NewCollector create new collector struct
-func NewCollector(url, clientID, auth string) (*Collector, error) {
-	c := Collector{}
+func NewCollector(url, clientID, auth string, logger log.Logger) (*Collector, error) {
+	c := Collector{logger: logger}
 
 	var err error
 	c.clientID, err = strconv.Atoi(clientID)
@@ -268,6 +425,7 @@ func NewCollector(url, clientID, auth string) (*Collector, error) {
 
 	c.auth = auth
 	c.qratorAPIURL = url
+	c.httpClient = newQratorAPIClient()
 	err = c.qratorCheck()
 	if err != nil {
 		return nil, err
@@ -291,128 +449,77 @@ func NewCollector(url, clientID, auth string) (*Collector, error) {
 		Help:      "Count of failed stats scrapes",
 	})
 
-	c.BypassedTraffic = *prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "bypassed_traffic",
-			Help:      "Bypassed traffic (bps)",
-		},
-		[]string{
-			"domain",
-		},
+	c.bypassedTrafficDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "bypassed_traffic"),
+		"Bypassed traffic (bps)",
+		[]string{"domain"}, nil,
 	)
 
-	c.IncomingTraffic = *prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "incoming_traffic",
-			Help:      "Incoming traffic (bps)",
-		},
-		[]string{
-			"domain",
-		},
+	c.incomingTrafficDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "incoming_traffic"),
+		"Incoming traffic (bps)",
+		[]string{"domain"}, nil,
 	)
 
-	c.OutgoingTraffic = *prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "outgoing_traffic",
-			Help:      "Outgoing traffic (bps)",
-		},
-		[]string{
-			"domain",
-		},
+	c.outgoingTrafficDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "outgoing_traffic"),
+		"Outgoing traffic (bps)",
+		[]string{"domain"}, nil,
 	)
 
-	c.BypassedPackets = *prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "bypassed_packets",
-			Help:      "Bypassed packets (pps)",
-		},
-		[]string{
-			"domain",
-		},
+	c.bypassedPacketsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "bypassed_packets"),
+		"Bypassed packets (pps)",
+		[]string{"domain"}, nil,
 	)
 
-	c.IncomingPackets = *prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "incoming_packets",
-			Help:      "Incoming packets (pps)",
-		},
-		[]string{
-			"domain",
-		},
+	c.incomingPacketsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "incoming_packets"),
+		"Incoming packets (pps)",
+		[]string{"domain"}, nil,
 	)
 
-	c.RequestRate = *prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "request_rate",
-			Help:      "Request rate (rps)",
-		},
-		[]string{
-			"domain",
-		},
+	c.requestRateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "request_rate"),
+		"Request rate (rps)",
+		[]string{"domain"}, nil,
 	)
 
-	c.SlowRequestsCount = *prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "slow_requests_count",
-			Help:      "Slow request count by treshold",
-		},
-		[]string{
-			"domain",
-			"treshold_seconds",
-		},
+	c.slowRequestsCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "slow_requests_count"),
+		"Slow request count by treshold",
+		[]string{"domain", "treshold_seconds"}, nil,
 	)
 
-	c.RequestsCount = *prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "requests_count_total",
-			Help:      "Requests count",
-		},
-		[]string{
-			"domain",
-		},
+	c.requestsCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "requests_count_total"),
+		"Requests count",
+		[]string{"domain"}, nil,
 	)
 
-	c.ErrorsCount = *prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "errors_count",
-			Help:      "Errors count by code",
-		},
-		[]string{
-			"domain",
-			"code",
-		},
+	c.errorsCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "errors_count"),
+		"Errors count by code",
+		[]string{"domain", "code"}, nil,
 	)
 
-	c.BannedIPs = *prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "banned_ip_addresses_count",
-			Help:      "Number of IPs banned by Qrator",
-		},
-		[]string{
-			"domain",
-			"source",
-		},
+	c.bannedIPsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "banned_ip_addresses_count"),
+		"Number of IPs banned by Qrator",
+		[]string{"domain", "source"}, nil,
 	)
 
-	c.BillableTraffic = *prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "billable_traffic",
-			Help:      "Billable traffic (Mbps)",
-		},
-		[]string{
-			"domain",
-		},
+	c.billableTrafficDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "billable_traffic"),
+		"Billable traffic (Mbps)",
+		[]string{"domain"}, nil,
+	)
+
+	c.scrapeWarningsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "exporter_last_scrape_warnings"),
+		"Non-fatal warnings encountered during the last scrape, labeled by domain and reason",
+		[]string{"domain", "reason"}, nil,
 	)
+
 	return &c, nil
 }
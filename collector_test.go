@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/go-kit/kit/log"
 )
 
 type jsonRequest struct {
@@ -120,7 +122,7 @@ func qratorTestServerDomain(w http.ResponseWriter, r *http.Request) {
 
 func TestNewCollector(t *testing.T) {
 	for _, tc := range NewCollectorTestCases {
-		_, err := NewCollector(tc.URL, tc.ClientID, tc.Secret)
+		_, err := NewCollector(tc.URL, tc.ClientID, tc.Secret, log.NewNopLogger())
 		if err != nil && !tc.ExpectedError {
 			t.Errorf("Error was not expected, got: %v", err)
 		} else if tc.ExpectedError && err == nil {
@@ -135,6 +137,8 @@ func TestDomains(t *testing.T) {
 			qratorAPIURL: tc.URL,
 			clientID:     clientID,
 			auth:         tc.Secret,
+			httpClient:   newQratorAPIClient(),
+			logger:       log.NewNopLogger(),
 		}
 		qds, err := c.getQratorDomains()
 		if err != nil && !tc.ExpectedError {
@@ -160,6 +164,8 @@ func TestDomainStats(t *testing.T) {
 			qratorAPIURL: tc.URL,
 			clientID:     clientID,
 			auth:         tc.Secret,
+			httpClient:   newQratorAPIClient(),
+			logger:       log.NewNopLogger(),
 		}
 		qd := qratorDomain{
 			ID: tc.DomainID,
@@ -1,27 +1,64 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
 
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-
-	"github.com/prometheus/common/log"
+	"github.com/prometheus/common/promlog"
+	promlogflag "github.com/prometheus/common/promlog/flag"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
 )
 
 func healthz(response http.ResponseWriter, request *http.Request) {
 	fmt.Fprintln(response, "ok")
 }
 
+// loadProbeAuth reads the optional QRATOR_PROBE_CONFIG file, a JSON object
+// mapping client_id to its X-Qrator-Auth secret, used to resolve
+// credentials for /probe requests that don't pass auth= explicitly.
+func loadProbeAuth(path string, logger log.Logger) map[string]string {
+	probeAuth := map[string]string{}
+	if path == "" {
+		return probeAuth
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		level.Error(logger).Log("msg", "Can't open QRATOR_PROBE_CONFIG file", "path", path, "err", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(&probeAuth); err != nil {
+		level.Error(logger).Log("msg", "Can't parse QRATOR_PROBE_CONFIG file", "path", path, "err", err)
+		os.Exit(1)
+	}
+	return probeAuth
+}
+
 func main() {
-	c, err := NewCollector("https://api.qrator.net/request", os.Getenv("QRATOR_CLIENT_ID"), os.Getenv("QRATOR_X_QRATOR_AUTH"))
+	promlogConfig := &promlog.Config{}
+	promlogflag.AddFlags(kingpin.CommandLine, promlogConfig)
+	kingpin.HelpFlag.Short('h')
+	kingpin.Parse()
+
+	logger := promlog.New(promlogConfig)
+
+	c, err := NewCollector("https://api.qrator.net/request", os.Getenv("QRATOR_CLIENT_ID"), os.Getenv("QRATOR_X_QRATOR_AUTH"), logger)
 	if err != nil {
-		log.Fatalf("Can't create collector: %v", err)
+		level.Error(logger).Log("msg", "Can't create collector", "err", err)
+		os.Exit(1)
 	}
 	prometheus.MustRegister(c)
+	probeAuth := loadProbeAuth(os.Getenv("QRATOR_PROBE_CONFIG"), logger)
 	http.Handle("/metrics", promhttp.Handler())
+	http.HandleFunc("/probe", probeHandler(logger, probeAuth))
 	http.HandleFunc("/healthz", healthz)
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`<html>
@@ -32,6 +69,9 @@ func main() {
 			</body>
 			</html>`))
 	})
-	log.Infoln("Starting qrator-exporter")
-	log.Fatal(http.ListenAndServe(":9502", nil))
+	level.Info(logger).Log("msg", "Starting qrator-exporter")
+	if err := http.ListenAndServe(":9502", nil); err != nil {
+		level.Error(logger).Log("msg", "Server exited", "err", err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// probeHandler implements a Blackbox-exporter-style /probe endpoint: given
+// a client_id and a target domain it builds a throwaway Collector scoped
+// to that single tenant/domain and renders just its metrics. This lets one
+// exporter process serve many Qrator accounts, with Prometheus picking the
+// client_id/target pair per scrape via relabel_configs instead of one
+// exporter per tenant.
+func probeHandler(logger log.Logger, probeAuth map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		clientID := params.Get("client_id")
+		target := params.Get("target")
+		if clientID == "" || target == "" {
+			http.Error(w, "client_id and target query parameters are required", http.StatusBadRequest)
+			return
+		}
+
+		auth := params.Get("auth")
+		if auth == "" {
+			auth = probeAuth[clientID]
+		}
+		if auth == "" {
+			http.Error(w, "no credentials known for client_id, pass auth= or configure QRATOR_PROBE_CONFIG", http.StatusBadRequest)
+			return
+		}
+
+		start := time.Now()
+		registry := prometheus.NewRegistry()
+		var mfs []*dto.MetricFamily
+
+		c, err := NewCollector("https://api.qrator.net/request", clientID, auth, logger)
+		if err != nil {
+			level.Error(logger).Log("msg", "Probe failed", "domain", target, "client_id", clientID, "err", err)
+		} else {
+			c.targetDomain = target
+			registry.MustRegister(c)
+			mfs, err = registry.Gather()
+			if err != nil {
+				level.Error(logger).Log("msg", "Probe failed to gather metrics", "domain", target, "client_id", clientID, "err", err)
+			}
+		}
+		duration := time.Since(start).Seconds()
+
+		success := 0.0
+		if err == nil && hasDomainSeries(mfs, target) {
+			success = 1
+		}
+
+		probeRegistry := prometheus.NewRegistry()
+		probeRegistry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "probe",
+			Name:      "success",
+			Help:      "Whether the Qrator probe succeeded",
+		}, func() float64 { return success }))
+		probeRegistry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "probe",
+			Name:      "duration_seconds",
+			Help:      "How long the probe took, in seconds",
+		}, func() float64 { return duration }))
+		probeMfs, _ := probeRegistry.Gather()
+
+		w.Header().Set("Content-Type", string(expfmt.FmtText))
+		enc := expfmt.NewEncoder(w, expfmt.FmtText)
+		for _, mf := range append(mfs, probeMfs...) {
+			if err := enc.Encode(mf); err != nil {
+				level.Error(logger).Log("msg", "Failed to encode probe metrics", "domain", target, "err", err)
+				return
+			}
+		}
+	}
+}
+
+// hasDomainSeries reports whether any metric family in mfs carries a
+// "domain" label matching domain, i.e. whether the probe actually found
+// and scraped that domain.
+func hasDomainSeries(mfs []*dto.MetricFamily, domain string) bool {
+	warningsName := prometheus.BuildFQName(namespace, "", "exporter_last_scrape_warnings")
+	for _, mf := range mfs {
+		// A warning series for the domain means its scrape failed.
+		if mf.GetName() == warningsName {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, lp := range m.GetLabel() {
+				if lp.GetName() == "domain" && lp.GetValue() == domain {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestFilterDomains(t *testing.T) {
+	qds := []qratorDomain{
+		{ID: 1, Name: "a.example.com"},
+		{ID: 2, Name: "b.example.com"},
+	}
+
+	filtered := filterDomains(qds, "b.example.com")
+	if len(filtered) != 1 {
+		t.Fatalf("Expected 1 domain, got %d", len(filtered))
+	}
+	if filtered[0].Name != "b.example.com" {
+		t.Errorf("Expected b.example.com, got: %s", filtered[0].Name)
+	}
+
+	if len(filterDomains(qds, "c.example.com")) != 0 {
+		t.Error("Expected no domains for an unknown target")
+	}
+}
+
+func TestHasDomainSeries(t *testing.T) {
+	name := "domain"
+	value := "a.example.com"
+	mfs := []*dto.MetricFamily{
+		{
+			Metric: []*dto.Metric{
+				{
+					Label: []*dto.LabelPair{
+						{Name: &name, Value: &value},
+					},
+				},
+			},
+		},
+	}
+
+	if !hasDomainSeries(mfs, "a.example.com") {
+		t.Error("Expected a.example.com to be found")
+	}
+	if hasDomainSeries(mfs, "b.example.com") {
+		t.Error("Did not expect b.example.com to be found")
+	}
+}